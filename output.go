@@ -0,0 +1,146 @@
+package anygroup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gkwa/anygroup/pkg/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// extractOutputFlag scans args for a "--output"/"-output" flag (either
+// "-output=json"/"--output=json" or "-output json"/"--output json"),
+// returning its value, the remaining args with it removed, and whether
+// it was found. It doesn't use the flag package because this flag must
+// be pulled out before singlechecker ever sees the remaining args, the
+// same way the "rewrite" subcommand is pulled out in Execute.
+func extractOutputFlag(args []string) (format string, rest []string, ok bool) {
+	for i, arg := range args {
+		trimmed := strings.TrimLeft(arg, "-")
+		if trimmed == arg {
+			continue // no leading "-" at all
+		}
+
+		if value, ok := strings.CutPrefix(trimmed, "output="); ok {
+			rest = append(append([]string{}, args[:i]...), args[i+1:]...)
+			return value, rest, true
+		}
+		if trimmed == "output" && i+1 < len(args) {
+			rest = append(append([]string{}, args[:i]...), args[i+2:]...)
+			return args[i+1], rest, true
+		}
+	}
+	return "", args, false
+}
+
+// runOutput runs analyzer.Analyzer directly against the packages named
+// by args and emits the resulting Declarations as format, bypassing
+// singlechecker entirely. This is necessary because singlechecker's own
+// -json flag reports Diagnostic{Pos, Message} pairs, flattening every
+// structured field (receiver, params, results, fields, doc, ...) into
+// one opaque message string — not the schema this flag promises.
+func runOutput(format string, args []string) int {
+	fs := analyzer.Analyzer.Flags
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return 1
+	}
+
+	var decls []analyzer.Declaration
+	for _, pkg := range pkgs {
+		pass := &analysis.Pass{
+			Analyzer:  analyzer.Analyzer,
+			Fset:      pkg.Fset,
+			Files:     pkg.Syntax,
+			Pkg:       pkg.Types,
+			TypesInfo: pkg.TypesInfo,
+			Report:    func(analysis.Diagnostic) {},
+		}
+		res, err := analyzer.Analyzer.Run(pass)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		decls = append(decls, res.(analyzer.Result).Declarations...)
+	}
+
+	if !analyzer.WithDocs() {
+		for i := range decls {
+			decls[i].Doc = ""
+		}
+	}
+
+	return emitDeclarations(format, decls)
+}
+
+// emitDeclarations writes decls to stdout in the requested format.
+func emitDeclarations(format string, decls []analyzer.Declaration) int {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(decls); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	case "ndjson":
+		enc := json.NewEncoder(os.Stdout)
+		for _, d := range decls {
+			if err := enc.Encode(d); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+	case "text":
+		for _, d := range decls {
+			printDeclarationText(d)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "anygroup: unknown --output value %q (want text, json, or ndjson)\n", format)
+		return 1
+	}
+	return 0
+}
+
+// printDeclarationText reproduces anygroup's original human-readable
+// dump: one "file: kind signature" line per declaration, preceded by
+// its doc comment (as a "// " block) when --with-docs is set.
+func printDeclarationText(d analyzer.Declaration) {
+	body := d.Kind + " " + d.Signature
+	if analyzer.WithDocs() && d.Doc != "" {
+		body = withDoc(body, d.Doc)
+	}
+	fmt.Printf("%s: %s\n", d.File, body)
+}
+
+// withDoc prepends doc as a "// " comment block above entry.
+func withDoc(entry, doc string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(doc, "\n"), "\n") {
+		b.WriteString("// ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString(entry)
+	return b.String()
+}
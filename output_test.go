@@ -0,0 +1,70 @@
+package anygroup
+
+import "testing"
+
+func TestExtractOutputFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantFormat string
+		wantRest   []string
+		wantOK     bool
+	}{
+		{
+			name:       "double dash equals",
+			args:       []string{"--output=json", "./..."},
+			wantFormat: "json",
+			wantRest:   []string{"./..."},
+			wantOK:     true,
+		},
+		{
+			name:       "single dash equals",
+			args:       []string{"-output=ndjson", "./..."},
+			wantFormat: "ndjson",
+			wantRest:   []string{"./..."},
+			wantOK:     true,
+		},
+		{
+			name:       "double dash separate value",
+			args:       []string{"--output", "text", "./..."},
+			wantFormat: "text",
+			wantRest:   []string{"./..."},
+			wantOK:     true,
+		},
+		{
+			name:       "single dash separate value",
+			args:       []string{"-output", "json", "./..."},
+			wantFormat: "json",
+			wantRest:   []string{"./..."},
+			wantOK:     true,
+		},
+		{
+			name:   "not present",
+			args:   []string{"-with-docs", "./..."},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, rest, ok := extractOutputFlag(tt.args)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if format != tt.wantFormat {
+				t.Errorf("format = %q, want %q", format, tt.wantFormat)
+			}
+			if len(rest) != len(tt.wantRest) {
+				t.Fatalf("rest = %v, want %v", rest, tt.wantRest)
+			}
+			for i := range rest {
+				if rest[i] != tt.wantRest[i] {
+					t.Errorf("rest[%d] = %q, want %q", i, rest[i], tt.wantRest[i])
+				}
+			}
+		})
+	}
+}
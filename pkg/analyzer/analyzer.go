@@ -0,0 +1,155 @@
+// Package analyzer exposes anygroup's declaration extraction as a
+// golang.org/x/tools/go/analysis Analyzer, so it can run under
+// singlechecker, multichecker, go vet-style pipelines, or gopls
+// alongside analyzers such as fillstruct and infertypeargs.
+package analyzer
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Result is the fact anygroup's Analyzer returns: every function,
+// struct, var, and const declaration found across the analyzed
+// package's files.
+type Result struct {
+	Declarations []Declaration
+}
+
+var withDocs bool
+
+// WithDocs reports whether -with-docs was passed, so callers that
+// bypass Analyzer's own diagnostic formatting (e.g. a structured
+// --output mode) can decide whether to include Declaration.Doc too.
+func WithDocs() bool {
+	return withDocs
+}
+
+// fast disables type-checked rendering. By default the Analyzer
+// resolves every type expression against pass.TypesInfo, so aliases,
+// generic instantiations, and identifiers embedded from other packages
+// render as their canonical types.Type string. -fast falls back to a
+// purely syntactic go/printer rendering of the AST as written, which
+// does not consult pass.TypesInfo. Packages are still loaded and
+// type-checked either way, and a package with type errors is not
+// analyzed.
+var fast bool
+
+var Analyzer = &analysis.Analyzer{
+	Name:       "anygroup",
+	Doc:        "extracts function, struct, var, and const declarations with fully rendered types",
+	Run:        run,
+	Flags:      newFlags(),
+	ResultType: reflect.TypeOf(Result{}),
+}
+
+func newFlags() flag.FlagSet {
+	fs := flag.NewFlagSet("anygroup", flag.ExitOnError)
+	fs.BoolVar(&withDocs, "with-docs", false, "include godoc/leading comments alongside each declaration")
+	fs.BoolVar(&fast, "fast", false, "skip type-checked rendering and print types exactly as written in source")
+	return *fs
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	var decls []Declaration
+
+	for _, file := range pass.Files {
+		cmap := ast.NewCommentMap(pass.Fset, file, file.Comments)
+		processed := make(map[string]bool)
+
+		report := func(pos token.Pos, d Declaration) {
+			key := fmt.Sprintf("%s|%s|%s", d.Kind, d.Name, d.Signature)
+			if processed[key] {
+				return
+			}
+			processed[key] = true
+			decls = append(decls, d)
+			pass.Reportf(pos, "%s", formatDiagnostic(d.Kind+" "+d.Signature, d.Doc))
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.FuncDecl:
+				p := pass.Fset.Position(node.Pos())
+				report(node.Pos(), Declaration{
+					File:       p.Filename,
+					Line:       p.Line,
+					Col:        p.Column,
+					Kind:       "function",
+					Name:       node.Name.Name,
+					Signature:  getFunctionSignature(pass, node),
+					Receiver:   receiverString(pass, node),
+					TypeParams: fieldListString(pass, node.Type.TypeParams),
+					Params:     fieldPairs(pass, node.Type.Params),
+					Results:    fieldPairs(pass, node.Type.Results),
+					Doc:        commentText(cmap, node),
+					Exported:   ast.IsExported(node.Name.Name),
+				})
+
+			case *ast.GenDecl:
+				switch node.Tok {
+				case token.TYPE:
+					for _, spec := range node.Specs {
+						ts, ok := spec.(*ast.TypeSpec)
+						if !ok {
+							continue
+						}
+						st, ok := ts.Type.(*ast.StructType)
+						if !ok {
+							continue
+						}
+						p := pass.Fset.Position(ts.Pos())
+						report(ts.Pos(), Declaration{
+							File:      p.Filename,
+							Line:      p.Line,
+							Col:       p.Column,
+							Kind:      "struct",
+							Name:      ts.Name.Name,
+							Signature: getStructDefinition(pass, ts.Name.Name, st),
+							Fields:    structFieldPairs(pass, st),
+							Doc:       commentText(cmap, ts, node),
+							Exported:  ast.IsExported(ts.Name.Name),
+						})
+					}
+				case token.VAR, token.CONST:
+					for _, spec := range node.Specs {
+						vs, ok := spec.(*ast.ValueSpec)
+						if !ok || len(vs.Names) == 0 {
+							continue
+						}
+						p := pass.Fset.Position(vs.Pos())
+						report(vs.Pos(), Declaration{
+							File:      p.Filename,
+							Line:      p.Line,
+							Col:       p.Column,
+							Kind:      node.Tok.String(),
+							Name:      vs.Names[0].Name,
+							Signature: getVariableDefinition(pass, vs),
+							Doc:       commentText(cmap, vs, node),
+							Exported:  ast.IsExported(vs.Names[0].Name),
+						})
+					}
+				}
+			}
+
+			return true
+		})
+	}
+
+	return Result{Declarations: decls}, nil
+}
+
+// formatDiagnostic prepends doc as a "// " comment block above entry
+// when -with-docs is set.
+func formatDiagnostic(entry, doc string) string {
+	if !withDocs || doc == "" {
+		return entry
+	}
+	return "// " + strings.ReplaceAll(doc, "\n", "\n// ") + "\n" + entry
+}
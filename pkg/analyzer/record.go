@@ -0,0 +1,27 @@
+package analyzer
+
+// Param describes one function parameter, result, struct field, or
+// generic type parameter.
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+// Declaration is the structured record produced for every function,
+// struct, var, and const declaration the Analyzer finds.
+type Declaration struct {
+	File       string   `json:"file"`
+	Line       int      `json:"line"`
+	Col        int      `json:"col"`
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Signature  string   `json:"signature,omitempty"`
+	Receiver   string   `json:"receiver,omitempty"`
+	TypeParams []string `json:"typeParams,omitempty"`
+	Params     []Param  `json:"params,omitempty"`
+	Results    []Param  `json:"results,omitempty"`
+	Fields     []Param  `json:"fields,omitempty"`
+	Doc        string   `json:"doc,omitempty"`
+	Exported   bool     `json:"exported"`
+}
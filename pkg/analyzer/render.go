@@ -0,0 +1,225 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// exprString renders expr exactly as it appears in the source, via
+// go/printer against pass.Fset.
+func exprString(pass *analysis.Pass, expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, pass.Fset, expr); err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return buf.String()
+}
+
+// typeString renders expr's canonical type: when pass.TypesInfo has
+// resolved it and -fast wasn't requested, this is types.TypeString
+// relative to pass.Pkg, so aliases, generic instantiations, and
+// identifiers embedded from other packages render as their real type
+// rather than however they happened to be spelled. Otherwise it falls
+// back to the syntactic go/printer rendering.
+//
+// A variadic parameter's *ast.Ellipsis is special-cased: TypesInfo
+// resolves it to the parameter's slice type (e.g. []string for
+// names ...string), which would silently drop the "..." marker. Render
+// it as "..." plus the type-checked element type instead.
+func typeString(pass *analysis.Pass, expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	if ell, ok := expr.(*ast.Ellipsis); ok {
+		return "..." + typeString(pass, ell.Elt)
+	}
+	if !fast && pass.TypesInfo != nil {
+		if t := pass.TypesInfo.TypeOf(expr); t != nil {
+			return types.TypeString(t, types.RelativeTo(pass.Pkg))
+		}
+	}
+	return exprString(pass, expr)
+}
+
+// fieldPairs renders a *ast.FieldList (params, results, generic type
+// parameters, or a receiver list) as name/type pairs, preserving the
+// "..." marker on a trailing variadic parameter.
+func fieldPairs(pass *analysis.Pass, fl *ast.FieldList) []Param {
+	if fl == nil {
+		return nil
+	}
+
+	var params []Param
+	for _, field := range fl.List {
+		typeStr := typeString(pass, field.Type)
+
+		if len(field.Names) == 0 {
+			params = append(params, Param{Type: typeStr})
+			continue
+		}
+		for _, name := range field.Names {
+			params = append(params, Param{Name: name.Name, Type: typeStr})
+		}
+	}
+	return params
+}
+
+// fieldListString renders fieldPairs(pass, fl) as "name type" strings
+// joined for display, e.g. in a function signature.
+func fieldListString(pass *analysis.Pass, fl *ast.FieldList) []string {
+	pairs := fieldPairs(pass, fl)
+	if pairs == nil {
+		return nil
+	}
+
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		if p.Name == "" {
+			parts[i] = p.Type
+			continue
+		}
+		parts[i] = fmt.Sprintf("%s %s", p.Name, p.Type)
+	}
+	return parts
+}
+
+// receiverString renders a method's receiver as "name T", or "" for a
+// plain function.
+func receiverString(pass *analysis.Pass, fn *ast.FuncDecl) string {
+	if fn.Recv == nil {
+		return ""
+	}
+	recvParts := fieldListString(pass, fn.Recv)
+	if len(recvParts) == 0 {
+		return ""
+	}
+	return strings.Join(recvParts, ", ")
+}
+
+func getFunctionSignature(pass *analysis.Pass, fn *ast.FuncDecl) string {
+	var recv string
+	if r := receiverString(pass, fn); r != "" {
+		recv = fmt.Sprintf("(%s) ", r)
+	}
+
+	var typeParams string
+	if fn.Type.TypeParams != nil {
+		typeParams = fmt.Sprintf("[%s]", strings.Join(fieldListString(pass, fn.Type.TypeParams), ", "))
+	}
+
+	params := strings.Join(fieldListString(pass, fn.Type.Params), ", ")
+
+	var results string
+	if resultParts := fieldListString(pass, fn.Type.Results); len(resultParts) == 1 {
+		results = " " + resultParts[0]
+	} else if len(resultParts) > 1 {
+		results = fmt.Sprintf(" (%s)", strings.Join(resultParts, ", "))
+	}
+
+	return fmt.Sprintf("%s%s%s(%s)%s", recv, fn.Name.Name, typeParams, params, results)
+}
+
+// structFieldPairs renders a struct's fields as name/type pairs, with the
+// raw field tag (backticks stripped) attached where present. An embedded
+// field carries its type as the name.
+func structFieldPairs(pass *analysis.Pass, st *ast.StructType) []Param {
+	var fields []Param
+	for _, field := range st.Fields.List {
+		typeStr := typeString(pass, field.Type)
+
+		var tag string
+		if field.Tag != nil {
+			tag = strings.Trim(field.Tag.Value, "`")
+		}
+
+		if len(field.Names) == 0 {
+			fields = append(fields, Param{Name: typeStr, Type: typeStr, Tag: tag})
+			continue
+		}
+		for _, name := range field.Names {
+			fields = append(fields, Param{Name: name.Name, Type: typeStr, Tag: tag})
+		}
+	}
+	return fields
+}
+
+func getStructDefinition(pass *analysis.Pass, structName string, st *ast.StructType) string {
+	var fields []string
+	for _, field := range st.Fields.List {
+		typeStr := typeString(pass, field.Type)
+
+		var tag string
+		if field.Tag != nil {
+			tag = " " + field.Tag.Value
+		}
+
+		if len(field.Names) == 0 {
+			// Embedded field: the type itself is the name.
+			fields = append(fields, typeStr+tag)
+			continue
+		}
+		for _, name := range field.Names {
+			fields = append(fields, fmt.Sprintf("%s %s%s", name.Name, typeStr, tag))
+		}
+	}
+	return fmt.Sprintf("type %s struct { %s }", structName, strings.Join(fields, "; "))
+}
+
+// getVariableDefinition renders a var/const ValueSpec, including its
+// declared type (if any) and its initializer expressions (if any). The
+// declared type is resolved against pass.TypesInfo like any other type
+// expression; initializer expressions are values, not types, so they're
+// always printed as written.
+func getVariableDefinition(pass *analysis.Pass, vs *ast.ValueSpec) string {
+	if len(vs.Names) == 0 {
+		return ""
+	}
+
+	var variables []string
+	for _, name := range vs.Names {
+		variables = append(variables, name.Name)
+	}
+
+	var typeStr string
+	if vs.Type != nil {
+		typeStr = " " + typeString(pass, vs.Type)
+	}
+
+	var valueStr string
+	if len(vs.Values) > 0 {
+		values := make([]string, len(vs.Values))
+		for i, v := range vs.Values {
+			values[i] = exprString(pass, v)
+		}
+		valueStr = " = " + strings.Join(values, ", ")
+	}
+
+	return fmt.Sprintf("%s%s%s", strings.Join(variables, ", "), typeStr, valueStr)
+}
+
+// commentText returns the first non-blank comment text found for nodes,
+// checked in order. This lets callers prefer a spec's own Doc (e.g. one
+// ValueSpec or TypeSpec inside a grouped GenDecl) over the enclosing
+// GenDecl's Doc, which only applies when the spec has none of its own.
+func commentText(cmap ast.CommentMap, nodes ...ast.Node) string {
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		for _, cg := range cmap[n] {
+			if text := strings.TrimSpace(cg.Text()); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
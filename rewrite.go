@@ -0,0 +1,534 @@
+package anygroup
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// runRewrite implements `anygroup rewrite`: it consolidates each file's
+// top-level var, const, import, and type declarations into one grouped
+// block per kind, the way gofmt consolidates formatting. Like gofmt's
+// -l and -d, it supports -list to just list files that would change and
+// -diff to print a unified diff instead of writing the result back.
+func runRewrite(args []string) int {
+	fs := flag.NewFlagSet("anygroup rewrite", flag.ExitOnError)
+	diff := fs.Bool("diff", false, "print a unified diff instead of rewriting the file")
+	list := fs.Bool("list", false, "print the names of files that would change")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	exit := 0
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			return rewriteFile(path, *diff, *list)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit = 1
+		}
+	}
+	return exit
+}
+
+// rewriteFile reads filename, groups its declarations, and then either
+// lists the filename, prints a diff, or writes the result back,
+// according to list and diff.
+func rewriteFile(filename string, diff, list bool) error {
+	fset := token.NewFileSet()
+
+	original, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	file, err := parser.ParseFile(fset, filename, original, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	formatted, err := groupDeclarations(fset, file, original, localPrefix(filename))
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	if bytes.Equal(original, formatted) {
+		return nil
+	}
+
+	switch {
+	case list:
+		fmt.Println(filename)
+	case diff:
+		d, err := unifiedDiff(filename, original, formatted)
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(d)
+	default:
+		return os.WriteFile(filename, formatted, 0o644)
+	}
+	return nil
+}
+
+// unifiedDiff shells out to the diff utility, the same approach gofmt's
+// -d flag historically used before go/printer grew a built-in differ.
+func unifiedDiff(filename string, before, after []byte) ([]byte, error) {
+	beforeFile, err := os.CreateTemp("", "anygroup-rewrite-before-*.go")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(beforeFile.Name())
+	defer beforeFile.Close()
+
+	afterFile, err := os.CreateTemp("", "anygroup-rewrite-after-*.go")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(afterFile.Name())
+	defer afterFile.Close()
+
+	if _, err := beforeFile.Write(before); err != nil {
+		return nil, err
+	}
+	if _, err := afterFile.Write(after); err != nil {
+		return nil, err
+	}
+
+	out, err := exec.Command("diff", "-u", beforeFile.Name(), afterFile.Name()).Output()
+	if len(out) == 0 && err != nil {
+		return nil, fmt.Errorf("diff %s: %w", filename, err)
+	}
+
+	// Replace the temp file names in the diff header with filename so
+	// the output reads like a normal patch against the real file.
+	header := fmt.Sprintf("--- %s\n+++ %s\n", filename, filename)
+	lines := strings.SplitN(string(out), "\n", 3)
+	if len(lines) == 3 {
+		return []byte(header + lines[2]), nil
+	}
+	return out, nil
+}
+
+// localPrefix returns the module path declared in the nearest go.mod
+// above filename, or "" if none is found. Imports under this prefix
+// are grouped as "local" rather than third-party.
+func localPrefix(filename string) string {
+	dir := filepath.Dir(filename)
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					return strings.TrimSpace(rest)
+				}
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// byteSpan is a [start, end) byte range into the original source.
+type byteSpan struct {
+	start, end int
+}
+
+// replacement overwrites original[start:end] with text once spliced.
+type replacement struct {
+	start, end int
+	text       string
+}
+
+// groupDeclarations consolidates file's top-level import, const, var,
+// and type declarations into one grouped block per kind, sorted in
+// stable order: imports by path (std, third-party, local groups, each
+// alphabetical), and const/var/type alphabetically by name. Each
+// group's merged text replaces the first original decl of that kind (so
+// it stays roughly where that kind first appeared); any further decls
+// of that kind are deleted. Doc comments follow their declaration into
+// the merged text, so a spec's own Doc wins over an enclosing
+// GenDecl.Doc that only applied because the spec used to be alone in
+// its decl.
+//
+// A const GenDecl that uses iota, directly or via an implicit-repetition
+// spec (one with no Type and no Values, which inherits the previous
+// spec's), is left untouched: merging it into a shared const block would
+// either reorder it away from the declaration it depends on, or shift
+// its iota position by splicing in specs from other const blocks, both
+// of which silently change its values.
+//
+// This works by splicing raw source text, not by combining ASTs: each
+// merged group is rendered and formatted as an independent, self
+// -contained fragment (its own private token.FileSet via go/format),
+// and everything outside the spans being replaced — every comment that
+// isn't part of a merged group, including a package doc comment or a
+// sibling function's doc — is left as exactly the original bytes. An
+// earlier version instead spliced the merged group's AST node into
+// file.Decls and reprinted the whole file with go/printer; that node's
+// positions came from a second, disjoint token.File sharing fset with
+// the original, so go/printer's position-based interleaving of
+// file.Comments against file.Decls misattributed or silently dropped
+// any comment outside the merged group.
+func groupDeclarations(fset *token.FileSet, file *ast.File, original []byte, modulePath string) ([]byte, error) {
+	cmap := ast.NewCommentMap(fset, file, file.Comments)
+
+	imports := newSpecBucket(token.IMPORT)
+	consts := newSpecBucket(token.CONST)
+	vars := newSpecBucket(token.VAR)
+	types := newSpecBucket(token.TYPE)
+	buckets := []*specBucket{imports, consts, vars, types}
+
+	for _, d := range file.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		if gd.Tok == token.CONST && constUsesIota(gd) {
+			continue
+		}
+
+		var bucket *specBucket
+		for _, b := range buckets {
+			if b.tok == gd.Tok {
+				bucket = b
+				break
+			}
+		}
+		if bucket == nil {
+			continue
+		}
+
+		for _, spec := range gd.Specs {
+			bucket.add(cmap, gd, spec)
+		}
+		bucket.spans = append(bucket.spans, declSpan(fset, original, gd))
+	}
+
+	var replacements []replacement
+	for _, b := range buckets {
+		if len(b.spans) == 0 {
+			continue
+		}
+
+		body, err := b.merge(fset, modulePath)
+		if err != nil {
+			return nil, err
+		}
+
+		first := b.spans[0]
+		replacements = append(replacements, replacement{start: first.start, end: first.end, text: body})
+		for _, sp := range b.spans[1:] {
+			replacements = append(replacements, replacement{start: sp.start, end: sp.end})
+		}
+	}
+
+	formatted, err := format.Source(splice(original, replacements))
+	if err != nil {
+		return nil, fmt.Errorf("reformatting after grouping: %w", err)
+	}
+	return formatted, nil
+}
+
+// declSpan returns the byte range of gd, extended to the start of the
+// line holding its leading Doc comment (or its own start, if it has
+// none) through the end of the line holding its last token. Swallowing
+// whole lines rather than stopping exactly at token boundaries means a
+// deleted decl doesn't leave stray indentation behind, and a decl with
+// a trailing same-line comment doesn't leave that comment orphaned.
+func declSpan(fset *token.FileSet, original []byte, gd *ast.GenDecl) byteSpan {
+	startPos := gd.Pos()
+	if gd.Doc != nil {
+		startPos = gd.Doc.Pos()
+	}
+	start := fset.Position(startPos).Offset
+	end := fset.Position(gd.End()).Offset
+	return byteSpan{start: lineStart(original, start), end: lineEnd(original, end)}
+}
+
+func lineStart(src []byte, offset int) int {
+	i := offset
+	for i > 0 && src[i-1] != '\n' {
+		i--
+	}
+	return i
+}
+
+func lineEnd(src []byte, offset int) int {
+	i := offset
+	for i < len(src) && src[i] != '\n' {
+		i++
+	}
+	if i < len(src) {
+		i++ // swallow the newline itself
+	}
+	return i
+}
+
+// splice returns original with each replacement's [start, end) span
+// overwritten by its text, applied in position order.
+func splice(original []byte, replacements []replacement) []byte {
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].start < replacements[j].start })
+
+	var out bytes.Buffer
+	prev := 0
+	for _, r := range replacements {
+		out.Write(original[prev:r.start])
+		out.WriteString(r.text)
+		prev = r.end
+	}
+	out.Write(original[prev:])
+	return out.Bytes()
+}
+
+// constUsesIota reports whether gd (assumed token.CONST) relies on
+// iota: either a spec's Values references the iota identifier directly,
+// or a spec has no Type and no Values, meaning it implicitly repeats the
+// previous spec's (including, transitively, that spec's iota).
+func constUsesIota(gd *ast.GenDecl) bool {
+	for i, spec := range gd.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if i > 0 && vs.Type == nil && len(vs.Values) == 0 {
+			return true
+		}
+		for _, v := range vs.Values {
+			if exprUsesIota(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func exprUsesIota(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == "iota" {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// specBucket collects every spec of one declaration kind found across a
+// file's (possibly several, possibly ungrouped) GenDecls of that kind,
+// plus the byte span of each original GenDecl they came from.
+type specBucket struct {
+	tok     token.Token
+	entries []specEntry
+	spans   []byteSpan
+}
+
+type specEntry struct {
+	doc  string
+	spec ast.Spec
+}
+
+func newSpecBucket(tok token.Token) *specBucket {
+	return &specBucket{tok: tok}
+}
+
+// add records spec (which belongs to gd), resolving its doc comment:
+// the spec's own Doc, then cmap[spec], then cmap[gd] when gd wraps
+// only this one spec.
+func (b *specBucket) add(cmap ast.CommentMap, gd *ast.GenDecl, spec ast.Spec) {
+	b.entries = append(b.entries, specEntry{doc: specDoc(cmap, gd, spec), spec: spec})
+}
+
+func specDoc(cmap ast.CommentMap, gd *ast.GenDecl, spec ast.Spec) string {
+	if doc := specDocGroup(spec); doc != nil {
+		return doc.Text()
+	}
+	if groups := cmap[spec]; len(groups) > 0 {
+		return groups[0].Text()
+	}
+	if len(gd.Specs) == 1 {
+		if groups := cmap[gd]; len(groups) > 0 {
+			return groups[0].Text()
+		}
+	}
+	return ""
+}
+
+func specDocGroup(spec ast.Spec) *ast.CommentGroup {
+	switch s := spec.(type) {
+	case *ast.ImportSpec:
+		return s.Doc
+	case *ast.ValueSpec:
+		return s.Doc
+	case *ast.TypeSpec:
+		return s.Doc
+	}
+	return nil
+}
+
+// merge renders every entry in the bucket as source text, sorted in
+// this kind's canonical order, and formats the result via go/format as
+// a standalone grouped declaration. go/format parses and prints against
+// its own private FileSet, entirely disjoint from fset, so the returned
+// text carries no position information that could collide with the
+// rest of the file once the caller splices it back in. It returns "" if
+// the bucket is empty.
+func (b *specBucket) merge(fset *token.FileSet, modulePath string) (string, error) {
+	if len(b.entries) == 0 {
+		return "", nil
+	}
+
+	entries := append([]specEntry(nil), b.entries...)
+
+	var body string
+	if b.tok == token.IMPORT {
+		body = renderImportGroup(fset, entries, modulePath)
+	} else {
+		sort.Slice(entries, func(i, j int) bool {
+			return specName(entries[i].spec) < specName(entries[j].spec)
+		})
+		body = renderSpecGroup(fset, b.tok, entries)
+	}
+
+	const header = "package p\n\n"
+	formatted, err := format.Source([]byte(header + body))
+	if err != nil {
+		return "", fmt.Errorf("rebuilding %s group: %w", b.tok, err)
+	}
+	return strings.TrimPrefix(string(formatted), header), nil
+}
+
+func specName(spec ast.Spec) string {
+	switch s := spec.(type) {
+	case *ast.ValueSpec:
+		return s.Names[0].Name
+	case *ast.TypeSpec:
+		return s.Name.Name
+	case *ast.ImportSpec:
+		return s.Path.Value
+	}
+	return ""
+}
+
+func renderSpecGroup(fset *token.FileSet, tok token.Token, entries []specEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (\n", tok)
+	for _, e := range entries {
+		writeDoc(&b, e.doc)
+		b.WriteString("\t" + renderBareSpec(fset, e.spec) + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// renderBareSpec prints spec without its own Doc comment, since the
+// caller already wrote that doc text itself; printing it again here
+// too would duplicate it once the spec round-trips through a prior
+// rewrite and the parser re-attaches Doc directly to the spec.
+func renderBareSpec(fset *token.FileSet, spec ast.Spec) string {
+	doc := specDocGroup(spec)
+	clearSpecDoc(spec)
+	defer restoreSpecDoc(spec, doc)
+
+	var buf bytes.Buffer
+	format.Node(&buf, fset, spec)
+	return buf.String()
+}
+
+func clearSpecDoc(spec ast.Spec) {
+	switch s := spec.(type) {
+	case *ast.ImportSpec:
+		s.Doc = nil
+	case *ast.ValueSpec:
+		s.Doc = nil
+	case *ast.TypeSpec:
+		s.Doc = nil
+	}
+}
+
+func restoreSpecDoc(spec ast.Spec, doc *ast.CommentGroup) {
+	switch s := spec.(type) {
+	case *ast.ImportSpec:
+		s.Doc = doc
+	case *ast.ValueSpec:
+		s.Doc = doc
+	case *ast.TypeSpec:
+		s.Doc = doc
+	}
+}
+
+// renderImportGroup sorts imports into std/third-party/local groups
+// (each alphabetical by path), separated by a blank line.
+func renderImportGroup(fset *token.FileSet, entries []specEntry, modulePath string) string {
+	var std, thirdParty, local []specEntry
+	for _, e := range entries {
+		path := strings.Trim(e.spec.(*ast.ImportSpec).Path.Value, `"`)
+		switch {
+		case modulePath != "" && (path == modulePath || strings.HasPrefix(path, modulePath+"/")):
+			local = append(local, e)
+		case !strings.Contains(strings.SplitN(path, "/", 2)[0], "."):
+			std = append(std, e)
+		default:
+			thirdParty = append(thirdParty, e)
+		}
+	}
+
+	groups := [][]specEntry{std, thirdParty, local}
+	for _, g := range groups {
+		sort.Slice(g, func(i, j int) bool { return specName(g[i].spec) < specName(g[j].spec) })
+	}
+
+	var b strings.Builder
+	b.WriteString("import (\n")
+	wroteGroup := false
+	for _, g := range groups {
+		if len(g) == 0 {
+			continue
+		}
+		if wroteGroup {
+			b.WriteString("\n")
+		}
+		wroteGroup = true
+		for _, e := range g {
+			writeDoc(&b, e.doc)
+			b.WriteString("\t" + renderBareSpec(fset, e.spec) + "\n")
+		}
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+func writeDoc(b *strings.Builder, doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(doc, "\n"), "\n") {
+		b.WriteString("\t// " + line + "\n")
+	}
+}
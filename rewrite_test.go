@@ -0,0 +1,170 @@
+package anygroup
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// group runs groupDeclarations against src and returns the rewritten
+// source text, so tests can assert on it.
+func group(t *testing.T, src string) string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	original := []byte(src)
+	file, err := parser.ParseFile(fset, "test.go", original, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+
+	got, err := groupDeclarations(fset, file, original, "example.com/m")
+	if err != nil {
+		t.Fatalf("groupDeclarations: %v", err)
+	}
+	return string(got)
+}
+
+func TestGroupDeclarationsConstIota(t *testing.T) {
+	const src = `package p
+
+const (
+	Zebra = iota
+	Apple
+	Mango
+)
+
+const Other = 1
+`
+	got := group(t, src)
+
+	if !strings.Contains(got, "Zebra = iota\n\tApple\n\tMango") {
+		t.Errorf("iota block was reordered or split:\n%s", got)
+	}
+	if !strings.Contains(got, "const (\n\tOther = 1\n)") {
+		t.Errorf("non-iota const was not grouped on its own:\n%s", got)
+	}
+
+	// The block check above keeps iota numbering Zebra, Apple, Mango 0,
+	// 1, 2, not whatever position the merged const block would have put
+	// them in; the rewritten file must also still parse.
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "test.go", got, 0); err != nil {
+		t.Fatalf("rewritten source does not parse: %v", err)
+	}
+}
+
+func TestGroupDeclarationsConstImplicitRepeat(t *testing.T) {
+	const src = `package p
+
+const (
+	B int = 1 << iota
+	KB
+	MB
+)
+`
+	got := group(t, src)
+
+	if !strings.Contains(got, "B int = 1 << iota\n\tKB\n\tMB") {
+		t.Errorf("implicit-repetition chain was reordered or split:\n%s", got)
+	}
+}
+
+func TestGroupDeclarationsDocPlacement(t *testing.T) {
+	const src = `package p
+
+// B is second.
+var B = 2
+
+// A is first.
+var A = 1
+`
+	got := group(t, src)
+
+	wantOrder := strings.Index(got, "// A is first.")
+	if wantOrder == -1 {
+		t.Fatalf("doc for A missing:\n%s", got)
+	}
+	gotBDoc := strings.Index(got, "// B is second.")
+	if gotBDoc == -1 || gotBDoc < wantOrder {
+		t.Fatalf("doc comments didn't follow their vars into sorted order:\n%s", got)
+	}
+}
+
+func TestGroupDeclarationsImportGrouping(t *testing.T) {
+	const src = `package p
+
+import "fmt"
+
+import "example.com/m/sub"
+
+import "os"
+`
+	got := group(t, src)
+
+	want := `import (
+	"fmt"
+	"os"
+
+	"example.com/m/sub"
+)`
+	if !strings.Contains(got, want) {
+		t.Errorf("imports not grouped std/local with blank separator, got:\n%s", got)
+	}
+}
+
+// TestGroupDeclarationsPreservesUnrelatedComments reproduces a realistic
+// file: a package doc comment, a documented var sandwiched between a
+// documented func on either side, and an ungrouped single var. None of
+// these comments belong to anything being merged, and all of them must
+// survive untouched.
+func TestGroupDeclarationsPreservesUnrelatedComments(t *testing.T) {
+	const src = `// Package p does things.
+package p
+
+// Before does something before.
+func Before() {}
+
+// B is second.
+var B = 2
+
+// Sandwiched sits between two merged vars.
+func Sandwiched() {}
+
+// A is first.
+var A = 1
+
+// After does something after.
+func After() {}
+`
+	got := group(t, src)
+
+	for _, want := range []string{
+		"// Package p does things.",
+		"// Before does something before.",
+		"func Before() {}",
+		"// Sandwiched sits between two merged vars.",
+		"func Sandwiched() {}",
+		"// After does something after.",
+		"func After() {}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("lost unrelated comment/decl %q, got:\n%s", want, got)
+		}
+	}
+
+	// The merged var doc comments must still be attached to the right
+	// var, not to each other or to an unrelated func.
+	if !strings.Contains(got, "// A is first.\n\tA = 1") {
+		t.Errorf("A's doc was dropped or misattributed, got:\n%s", got)
+	}
+	if !strings.Contains(got, "// B is second.\n\tB = 2") {
+		t.Errorf("B's doc was dropped or misattributed, got:\n%s", got)
+	}
+
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "test.go", got, 0); err != nil {
+		t.Fatalf("rewritten source does not parse: %v", err)
+	}
+}